@@ -0,0 +1,132 @@
+// Package canonical canonicalizes JSON documents under a choice of
+// well-known schemes so that callers can pick whichever one their
+// downstream verifier (TUF, in-toto, Notary, ...) expects.
+package canonical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+)
+
+// Mode selects the canonicalization scheme used by Marshal.
+type Mode int
+
+const (
+	// ModeJCS produces RFC 8785 JSON Canonicalization Scheme output.
+	ModeJCS Mode = iota
+	// ModeOLPC produces OLPC Canonical JSON output, as used by TUF,
+	// in-toto and Notary. Unlike JCS it has no defined encoding for
+	// floating point numbers, so non-integer numbers are rejected.
+	ModeOLPC
+)
+
+// Marshal canonicalizes the JSON document in data according to mode.
+func Marshal(data []byte, mode Mode) ([]byte, error) {
+	switch mode {
+	case ModeJCS:
+		return jsoncanonicalizer.Transform(data)
+	case ModeOLPC:
+		return marshalOLPC(data)
+	default:
+		return nil, fmt.Errorf("canonical: unknown mode %d", mode)
+	}
+}
+
+func marshalOLPC(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("canonical: %w", err)
+	}
+	if err := decoder.Decode(new(interface{})); err != io.EOF {
+		return nil, fmt.Errorf("canonical: trailing data after top-level value")
+	}
+
+	var buf bytes.Buffer
+	if err := encodeOLPC(&buf, v); err != nil {
+		return nil, fmt.Errorf("canonical: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeOLPC(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if val {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		return encodeOLPCNumber(buf, val)
+	case string:
+		encodeOLPCString(buf, val)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeOLPC(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeOLPCString(buf, k)
+			buf.WriteByte(':')
+			if err := encodeOLPC(buf, val[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
+
+func encodeOLPCNumber(buf *bytes.Buffer, n json.Number) error {
+	if strings.ContainsAny(string(n), ".eE") {
+		return fmt.Errorf("OLPC canonical JSON does not define an encoding for non-integer number %q", n)
+	}
+	i, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return fmt.Errorf("invalid integer number %q", n)
+	}
+	buf.WriteString(i.String())
+	return nil
+}
+
+func encodeOLPCString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		if r == '\\' || r == '"' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,80 @@
+package canonical
+
+import "testing"
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		mode    Mode
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "jcs sorts keys and normalizes numbers",
+			input: `{"b":1,"a":1.0,"c":[3,2,1]}`,
+			mode:  ModeJCS,
+			want:  `{"a":1,"b":1,"c":[3,2,1]}`,
+		},
+		{
+			name:  "jcs keeps fractional numbers",
+			input: `{"price": 1.50}`,
+			mode:  ModeJCS,
+			want:  `{"price":1.5}`,
+		},
+		{
+			name:  "olpc sorts keys",
+			input: `{"b":1,"a":2,"c":[3,2,1]}`,
+			mode:  ModeOLPC,
+			want:  `{"a":2,"b":1,"c":[3,2,1]}`,
+		},
+		{
+			name:  "olpc escapes strings minimally",
+			input: `{"s":"a\"b\\c"}`,
+			mode:  ModeOLPC,
+			want:  `{"s":"a\"b\\c"}`,
+		},
+		{
+			name:  "olpc encodes arbitrary-precision integers",
+			input: `{"n":123456789012345678901234567890}`,
+			mode:  ModeOLPC,
+			want:  `{"n":123456789012345678901234567890}`,
+		},
+		{
+			name:    "olpc rejects floats",
+			input:   `{"n":1.5}`,
+			mode:    ModeOLPC,
+			wantErr: true,
+		},
+		{
+			name:    "olpc rejects trailing garbage",
+			input:   `{"a":1}garbage`,
+			mode:    ModeOLPC,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal([]byte(tt.input), tt.mode)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Marshal(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Marshal(%q) returned error: %v", tt.input, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalUnknownMode(t *testing.T) {
+	if _, err := Marshal([]byte(`{}`), Mode(99)); err == nil {
+		t.Fatal("Marshal with unknown mode: want error, got nil")
+	}
+}
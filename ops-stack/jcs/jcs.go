@@ -0,0 +1,230 @@
+// Package jcs implements a streaming variant of the RFC 8785 JSON
+// Canonicalization Scheme, for feeds (ticker/market-data and the like)
+// that are too large to hold twice in memory as a parsed tree.
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+)
+
+// Encoder writes the canonical form of successive JSON values to an
+// underlying io.Writer.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder that writes canonical JSON to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode canonicalizes v and writes it to the underlying writer.
+func (e *Encoder) Encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("jcs: %w", err)
+	}
+	return Transform(bytes.NewReader(data), e.w)
+}
+
+// Transform reads a single JSON document from r and writes its
+// canonical form to w, sorting object keys as they are encountered
+// rather than loading the whole document into a parsed tree first.
+func Transform(r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := encodeValue(w, dec); err != nil {
+		return fmt.Errorf("jcs: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("jcs: trailing data after top-level value")
+	}
+	return nil
+}
+
+func encodeValue(w io.Writer, dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return encodeToken(w, dec, tok)
+}
+
+func encodeToken(w io.Writer, dec *json.Decoder, tok json.Token) error {
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return encodeObject(w, dec)
+		case '[':
+			return encodeArray(w, dec)
+		default:
+			return fmt.Errorf("unexpected delimiter %q", t)
+		}
+	case string:
+		writeString(w, t)
+		return nil
+	case json.Number:
+		return writeNumber(w, t)
+	case bool:
+		if t {
+			_, err := io.WriteString(w, "true")
+			return err
+		}
+		_, err := io.WriteString(w, "false")
+		return err
+	case nil:
+		_, err := io.WriteString(w, "null")
+		return err
+	default:
+		return fmt.Errorf("unsupported token %T", tok)
+	}
+}
+
+// encodeArray streams elements straight through to w: array order is
+// already canonical, so siblings never need to be buffered together.
+func encodeArray(w io.Writer, dec *json.Decoder) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok && delim == ']' {
+			break
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := encodeToken(w, dec, tok); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// encodeObject buffers each member's already-canonical bytes just long
+// enough to sort keys, then streams the sorted members out; it never
+// holds the object's parsed value tree, only its encoded children.
+func encodeObject(w io.Writer, dec *json.Decoder) error {
+	type member struct {
+		key   string
+		value []byte
+	}
+	var members []member
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok && delim == '}' {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected object key token %T", tok)
+		}
+
+		var buf bytes.Buffer
+		if err := encodeValue(&buf, dec); err != nil {
+			return err
+		}
+		members = append(members, member{key: key, value: buf.Bytes()})
+	}
+
+	// RFC 8785 orders keys by their UTF-16 code units, which differs from
+	// UTF-8 byte order for characters above U+FFFF.
+	sort.Slice(members, func(i, j int) bool {
+		a := utf16.Encode([]rune(members[i].key))
+		b := utf16.Encode([]rune(members[j].key))
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	for i := 1; i < len(members); i++ {
+		if members[i].key == members[i-1].key {
+			return fmt.Errorf("duplicate key: %s", members[i].key)
+		}
+	}
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, m := range members {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		writeString(w, m.key)
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if _, err := w.Write(m.value); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// JSON standard escapes, excluding \u, in the order RFC 8785 expects.
+var asciiEscapes = []byte{'\\', '"', 'b', 'f', 'n', 'r', 't'}
+var binaryEscapes = []byte{'\\', '"', '\b', '\f', '\n', '\r', '\t'}
+
+// writeString quotes and escapes s the way RFC 8785 requires: the JSON
+// standard backslash escapes, \u00hh for other control characters, and
+// every other byte (including UTF-8 continuation bytes) passed through
+// unchanged. Unlike encoding/json.Marshal this never escapes '<', '>'
+// or '&', so output matches jsoncanonicalizer.Transform byte for byte.
+func writeString(w io.Writer, s string) {
+	io.WriteString(w, `"`)
+CoreLoop:
+	for _, c := range []byte(s) {
+		for i, esc := range binaryEscapes {
+			if esc == c {
+				io.WriteString(w, "\\"+string(asciiEscapes[i]))
+				continue CoreLoop
+			}
+		}
+		if c < 0x20 {
+			fmt.Fprintf(w, "\\u%04x", c)
+			continue
+		}
+		w.Write([]byte{c})
+	}
+	io.WriteString(w, `"`)
+}
+
+func writeNumber(w io.Writer, n json.Number) error {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", n, err)
+	}
+	formatted, err := jsoncanonicalizer.NumberToJSON(f)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", n, err)
+	}
+	_, err = io.WriteString(w, formatted)
+	return err
+}
@@ -0,0 +1,152 @@
+package jcs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+)
+
+func transform(t *testing.T, input string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Transform(strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("Transform(%q) returned error: %v", input, err)
+	}
+	return buf.String()
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "sorts object keys",
+			input: `{"b":1,"a":2}`,
+			want:  `{"a":2,"b":1}`,
+		},
+		{
+			name:  "leaves array order alone",
+			input: `{"a":[3,2,1]}`,
+			want:  `{"a":[3,2,1]}`,
+		},
+		{
+			name:  "normalizes numbers like jsoncanonicalizer",
+			input: `{"a":1.0,"b":1e2,"c":1.50,"d":3e10,"e":-0}`,
+			want:  `{"a":1,"b":100,"c":1.5,"d":30000000000,"e":0}`,
+		},
+		{
+			name:  "does not HTML-escape strings",
+			input: `{"s":"<tag>&amp;"}`,
+			want:  `{"s":"<tag>&amp;"}`,
+		},
+		{
+			name:  "escapes control characters",
+			input: "{\"s\":\"a\\nb\"}",
+			want:  `{"s":"a\nb"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transform(t, tt.input)
+			if got != tt.want {
+				t.Errorf("Transform(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			want, err := jsoncanonicalizer.Transform([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("reference jsoncanonicalizer.Transform(%q) returned error: %v", tt.input, err)
+			}
+			if got != string(want) {
+				t.Errorf("Transform(%q) = %q, want reference output %q", tt.input, got, want)
+			}
+		})
+	}
+}
+
+func TestTransformRejectsDuplicateKeys(t *testing.T) {
+	var buf bytes.Buffer
+	err := Transform(strings.NewReader(`{"a":1,"a":2}`), &buf)
+	if err == nil {
+		t.Fatal("Transform with duplicate key: want error, got nil")
+	}
+}
+
+func TestTransformRejectsTrailingData(t *testing.T) {
+	var buf bytes.Buffer
+	err := Transform(strings.NewReader(`{"a":1}garbage`), &buf)
+	if err == nil {
+		t.Fatal("Transform with trailing data: want error, got nil")
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(map[string]interface{}{"b": 1, "a": 2}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if got, want := buf.String(), `{"a":2,"b":1}`; got != want {
+		t.Errorf("Encode() wrote %q, want %q", got, want)
+	}
+}
+
+// genTicks builds n newline-delimited market-tick JSON objects, roughly
+// matching the sample payload in cmd/sign, for the benchmarks below.
+func genTicks(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		buf.WriteString(`{"market":"cryptocurrency","ticker":"BTC-USD","price":50000.5,"timestamp":1234567890,"volume":1000000}`)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func benchmarkTransform(b *testing.B, size int) {
+	data := genTicks(size)
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	var buf bytes.Buffer
+	b.ResetTimer()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		for _, line := range lines {
+			if err := Transform(bytes.NewReader(line), &buf); err != nil {
+				b.Fatal(err)
+			}
+			buf.WriteByte('\n')
+		}
+	}
+}
+
+// ticksFor targets roughly the given payload size in bytes, given each
+// generated tick line is 103 bytes including its newline.
+func ticksFor(bytes int) int {
+	const lineSize = 103
+	return bytes / lineSize
+}
+
+func BenchmarkTransform1MB(b *testing.B)   { benchmarkTransform(b, ticksFor(1<<20)) }
+func BenchmarkTransform10MB(b *testing.B)  { benchmarkTransform(b, ticksFor(10<<20)) }
+func BenchmarkTransform100MB(b *testing.B) { benchmarkTransform(b, ticksFor(100<<20)) }
+
+func benchmarkReferenceTransform(b *testing.B, size int) {
+	data := genTicks(size)
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	b.ResetTimer()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			if _, err := jsoncanonicalizer.Transform(line); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReferenceTransform1MB(b *testing.B)   { benchmarkReferenceTransform(b, ticksFor(1<<20)) }
+func BenchmarkReferenceTransform10MB(b *testing.B)  { benchmarkReferenceTransform(b, ticksFor(10<<20)) }
+func BenchmarkReferenceTransform100MB(b *testing.B) { benchmarkReferenceTransform(b, ticksFor(100<<20)) }
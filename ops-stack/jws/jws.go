@@ -0,0 +1,236 @@
+// Package jws produces and verifies JWS signatures (RFC 7515) whose
+// payload is JSON canonicalized with JCS before signing and carried
+// unencoded ("b64":false, RFC 7797), the pattern used by verifiable
+// credential and DID ecosystems built on RFC 8785.
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+)
+
+// ecdsaP256Size is the byte width of each of R and S in a P-256
+// signature, as required by RFC 7518 §3.4 for ES256.
+const ecdsaP256Size = 32
+
+// Alg identifies the JWS signature algorithm.
+type Alg string
+
+const (
+	EdDSA Alg = "EdDSA"
+	ES256 Alg = "ES256"
+	RS256 Alg = "RS256"
+)
+
+// SigningKey bundles the key material and metadata needed to produce a
+// JWS: the algorithm to use, an optional key ID echoed in the header,
+// and the underlying signer.
+type SigningKey struct {
+	Alg    Alg
+	KeyID  string
+	Signer crypto.Signer
+}
+
+// VerificationKey bundles the key material and metadata needed to
+// verify a JWS. Strict requires the decoded payload to already be in
+// canonical JCS form, rejecting tokens whose payload was tampered with
+// or was never canonicalized before signing.
+type VerificationKey struct {
+	Alg    Alg
+	Public crypto.PublicKey
+	Strict bool
+}
+
+// header is the JOSE header. b64 is always false: per RFC 7797 the
+// payload is carried unencoded so that it stays valid, human-readable
+// canonical JSON on the wire.
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Cty  string   `json:"cty"`
+	B64  bool     `json:"b64"`
+	Crit []string `json:"crit"`
+}
+
+// SignCanonical canonicalizes payload with JCS and returns a JWS
+// Compact Serialization over it.
+func SignCanonical(payload map[string]interface{}, key SigningKey) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("jws: marshal payload: %w", err)
+	}
+	canon, err := jsoncanonicalizer.Transform(raw)
+	if err != nil {
+		return "", fmt.Errorf("jws: canonicalize payload: %w", err)
+	}
+
+	h := header{Alg: string(key.Alg), Kid: key.KeyID, Cty: "json", B64: false, Crit: []string{"b64"}}
+	headerJSON, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("jws: marshal header: %w", err)
+	}
+	headerB64 := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	signingInput := headerB64 + "." + string(canon)
+	sigBytes, err := rawSign(key, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("jws: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sigBytes), nil
+}
+
+// VerifyCanonical verifies a JWS Compact Serialization produced by
+// SignCanonical and returns the decoded payload bytes. If key.Strict is
+// set, VerifyCanonical rejects tokens whose payload is not already in
+// canonical JCS form.
+func VerifyCanonical(token string, key VerificationKey) ([]byte, error) {
+	headerB64, payload, sigB64, err := splitCompact(token)
+	if err != nil {
+		return nil, fmt.Errorf("jws: %w", err)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return nil, fmt.Errorf("jws: decode header: %w", err)
+	}
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return nil, fmt.Errorf("jws: unmarshal header: %w", err)
+	}
+	if h.B64 {
+		return nil, errors.New("jws: expected \"b64\":false header")
+	}
+	if Alg(h.Alg) != key.Alg {
+		return nil, fmt.Errorf("jws: header alg %q does not match verification key alg %q", h.Alg, key.Alg)
+	}
+
+	if key.Strict {
+		canon, err := jsoncanonicalizer.Transform([]byte(payload))
+		if err != nil {
+			return nil, fmt.Errorf("jws: payload is not valid JSON: %w", err)
+		}
+		if string(canon) != payload {
+			return nil, errors.New("jws: payload is not in canonical JCS form")
+		}
+	}
+
+	sigBytes, err := base64.RawURLEncoding.DecodeString(sigB64)
+	if err != nil {
+		return nil, fmt.Errorf("jws: decode signature: %w", err)
+	}
+
+	signingInput := headerB64 + "." + payload
+	if err := rawVerify(key, []byte(signingInput), sigBytes); err != nil {
+		return nil, fmt.Errorf("jws: %w", err)
+	}
+
+	return []byte(payload), nil
+}
+
+// splitCompact splits a Compact Serialization token into its three
+// segments. The payload segment is carried unencoded (b64:false) and
+// may itself contain '.' characters, so only the first and last dots
+// are treated as segment boundaries.
+func splitCompact(token string) (header, payload, sig string, err error) {
+	first := strings.IndexByte(token, '.')
+	last := strings.LastIndexByte(token, '.')
+	if first < 0 || last <= first {
+		return "", "", "", errors.New("malformed compact serialization")
+	}
+	return token[:first], token[first+1 : last], token[last+1:], nil
+}
+
+func rawSign(key SigningKey, signingInput []byte) ([]byte, error) {
+	switch key.Alg {
+	case EdDSA:
+		if _, ok := key.Signer.Public().(ed25519.PublicKey); !ok {
+			return nil, fmt.Errorf("EdDSA requires an ed25519 key, got %T", key.Signer.Public())
+		}
+		return key.Signer.Sign(nil, signingInput, crypto.Hash(0))
+	case ES256:
+		if _, ok := key.Signer.Public().(*ecdsa.PublicKey); !ok {
+			return nil, fmt.Errorf("ES256 requires an *ecdsa.PublicKey, got %T", key.Signer.Public())
+		}
+		digest := sha256.Sum256(signingInput)
+		der, err := key.Signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsaRawFromASN1(der, ecdsaP256Size)
+	case RS256:
+		if _, ok := key.Signer.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("RS256 requires an *rsa.PublicKey, got %T", key.Signer.Public())
+		}
+		digest := sha256.Sum256(signingInput)
+		return key.Signer.Sign(nil, digest[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", key.Alg)
+	}
+}
+
+// ecdsaRawFromASN1 converts the ASN.1 DER signature produced by
+// crypto.Signer.Sign for an ECDSA key into the fixed-width R || S
+// concatenation RFC 7518 §3.4 requires for ES256.
+func ecdsaRawFromASN1(der []byte, size int) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("decode ECDSA signature: %w", err)
+	}
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+func rawVerify(key VerificationKey, signingInput, sig []byte) error {
+	switch key.Alg {
+	case EdDSA:
+		pk, ok := key.Public.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("EdDSA requires an ed25519.PublicKey, got %T", key.Public)
+		}
+		if !ed25519.Verify(pk, signingInput, sig) {
+			return errors.New("invalid EdDSA signature")
+		}
+	case ES256:
+		pk, ok := key.Public.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("ES256 requires a *ecdsa.PublicKey, got %T", key.Public)
+		}
+		if len(sig) != 2*ecdsaP256Size {
+			return fmt.Errorf("invalid ES256 signature length %d, want %d", len(sig), 2*ecdsaP256Size)
+		}
+		r := new(big.Int).SetBytes(sig[:ecdsaP256Size])
+		s := new(big.Int).SetBytes(sig[ecdsaP256Size:])
+		digest := sha256.Sum256(signingInput)
+		if !ecdsa.Verify(pk, digest[:], r, s) {
+			return errors.New("invalid ES256 signature")
+		}
+	case RS256:
+		pk, ok := key.Public.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("RS256 requires a *rsa.PublicKey, got %T", key.Public)
+		}
+		digest := sha256.Sum256(signingInput)
+		if err := rsa.VerifyPKCS1v15(pk, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("invalid RS256 signature: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q", key.Alg)
+	}
+	return nil
+}
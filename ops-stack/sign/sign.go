@@ -0,0 +1,157 @@
+// Package sign produces and verifies detached signatures over the
+// canonical form of a JSON document, in the style used by TUF, in-toto
+// and Notary for tamper-evident metadata and audit trails.
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/EvezArt/codex/ops-stack/canonical"
+)
+
+// Alg identifies the signature algorithm used for a Signature.
+type Alg string
+
+const (
+	// AlgEd25519 signs the canonical document directly (no pre-hash).
+	AlgEd25519 Alg = "Ed25519"
+	// AlgECDSAP256 signs a SHA-256 digest of the canonical document.
+	AlgECDSAP256 Alg = "ECDSA-P256"
+	// AlgRSAPSS signs a SHA-256 digest of the canonical document using RSA-PSS.
+	AlgRSAPSS Alg = "RSA-PSS"
+)
+
+// Signature is the detached signature envelope: the algorithm used,
+// the SHA-256 of the signer's SPKI as a key identifier, and the
+// base64-encoded signature bytes. It is itself serialized as canonical
+// JSON by Canonical.
+type Signature struct {
+	Alg   Alg    `json:"alg"`
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Canonical returns the Signature envelope as its own canonical JSON blob.
+func (s Signature) Canonical() ([]byte, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	return canonical.Marshal(raw, canonical.ModeJCS)
+}
+
+// Sign canonicalizes doc and signs it with key under alg, returning a
+// detached Signature envelope.
+func Sign(doc []byte, key crypto.Signer, alg Alg) (Signature, error) {
+	canon, err := canonical.Marshal(doc, canonical.ModeJCS)
+	if err != nil {
+		return Signature{}, fmt.Errorf("sign: canonicalize: %w", err)
+	}
+
+	keyID, err := keyID(key.Public())
+	if err != nil {
+		return Signature{}, fmt.Errorf("sign: %w", err)
+	}
+
+	sigBytes, err := rawSign(key, alg, canon)
+	if err != nil {
+		return Signature{}, fmt.Errorf("sign: %w", err)
+	}
+
+	return Signature{
+		Alg:   alg,
+		KeyID: keyID,
+		Sig:   base64.StdEncoding.EncodeToString(sigBytes),
+	}, nil
+}
+
+// Verify canonicalizes doc and checks sig against it using pub.
+func Verify(doc []byte, sig Signature, pub crypto.PublicKey) error {
+	canon, err := canonical.Marshal(doc, canonical.ModeJCS)
+	if err != nil {
+		return fmt.Errorf("sign: canonicalize: %w", err)
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(sig.Sig)
+	if err != nil {
+		return fmt.Errorf("sign: decode signature: %w", err)
+	}
+
+	switch sig.Alg {
+	case AlgEd25519:
+		pk, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("sign: want ed25519.PublicKey, got %T", pub)
+		}
+		if !ed25519.Verify(pk, canon, sigBytes) {
+			return errors.New("sign: invalid Ed25519 signature")
+		}
+	case AlgECDSAP256:
+		pk, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("sign: want *ecdsa.PublicKey, got %T", pub)
+		}
+		digest := sha256.Sum256(canon)
+		if !ecdsa.VerifyASN1(pk, digest[:], sigBytes) {
+			return errors.New("sign: invalid ECDSA signature")
+		}
+	case AlgRSAPSS:
+		pk, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("sign: want *rsa.PublicKey, got %T", pub)
+		}
+		digest := sha256.Sum256(canon)
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: crypto.SHA256}
+		if err := rsa.VerifyPSS(pk, crypto.SHA256, digest[:], sigBytes, opts); err != nil {
+			return fmt.Errorf("sign: invalid RSA-PSS signature: %w", err)
+		}
+	default:
+		return fmt.Errorf("sign: unknown algorithm %q", sig.Alg)
+	}
+	return nil
+}
+
+func rawSign(key crypto.Signer, alg Alg, canon []byte) ([]byte, error) {
+	switch alg {
+	case AlgEd25519:
+		if _, ok := key.Public().(ed25519.PublicKey); !ok {
+			return nil, fmt.Errorf("want ed25519 key, got %T", key.Public())
+		}
+		return key.Sign(rand.Reader, canon, crypto.Hash(0))
+	case AlgECDSAP256:
+		if _, ok := key.Public().(*ecdsa.PublicKey); !ok {
+			return nil, fmt.Errorf("want *ecdsa.PublicKey, got %T", key.Public())
+		}
+		digest := sha256.Sum256(canon)
+		return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgRSAPSS:
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("want *rsa.PublicKey, got %T", key.Public())
+		}
+		digest := sha256.Sum256(canon)
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: crypto.SHA256}
+		return key.Sign(rand.Reader, digest[:], opts)
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", alg)
+	}
+}
+
+func keyID(pub crypto.PublicKey) (string, error) {
+	spki, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("marshal SPKI: %w", err)
+	}
+	sum := sha256.Sum256(spki)
+	return hex.EncodeToString(sum[:]), nil
+}
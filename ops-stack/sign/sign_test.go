@@ -0,0 +1,112 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func genKey(t *testing.T, alg Alg) (crypto.Signer, crypto.PublicKey) {
+	t.Helper()
+	switch alg {
+	case AlgEd25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("ed25519.GenerateKey: %v", err)
+		}
+		return priv, pub
+	case AlgECDSAP256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("ecdsa.GenerateKey: %v", err)
+		}
+		return priv, &priv.PublicKey
+	case AlgRSAPSS:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("rsa.GenerateKey: %v", err)
+		}
+		return priv, &priv.PublicKey
+	default:
+		t.Fatalf("genKey: unknown algorithm %q", alg)
+		return nil, nil
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	doc := []byte(`{"ticker":"BTC-USD","price":50000}`)
+
+	for _, alg := range []Alg{AlgEd25519, AlgECDSAP256, AlgRSAPSS} {
+		t.Run(string(alg), func(t *testing.T) {
+			signer, pub := genKey(t, alg)
+
+			sig, err := Sign(doc, signer, alg)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if sig.Alg != alg {
+				t.Errorf("Sign: Alg = %q, want %q", sig.Alg, alg)
+			}
+
+			if err := Verify(doc, sig, pub); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTamperedDoc(t *testing.T) {
+	for _, alg := range []Alg{AlgEd25519, AlgECDSAP256, AlgRSAPSS} {
+		t.Run(string(alg), func(t *testing.T) {
+			signer, pub := genKey(t, alg)
+
+			sig, err := Sign([]byte(`{"amount":1}`), signer, alg)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			if err := Verify([]byte(`{"amount":2}`), sig, pub); err == nil {
+				t.Error("Verify with a tampered document: want error, got nil")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsWrongKey(t *testing.T) {
+	for _, alg := range []Alg{AlgEd25519, AlgECDSAP256, AlgRSAPSS} {
+		t.Run(string(alg), func(t *testing.T) {
+			signer, _ := genKey(t, alg)
+			_, otherPub := genKey(t, alg)
+
+			doc := []byte(`{"amount":1}`)
+			sig, err := Sign(doc, signer, alg)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			if err := Verify(doc, sig, otherPub); err == nil {
+				t.Error("Verify with the wrong public key: want error, got nil")
+			}
+		})
+	}
+}
+
+func TestSignatureCanonical(t *testing.T) {
+	signer, _ := genKey(t, AlgEd25519)
+	sig, err := Sign([]byte(`{"amount":1}`), signer, AlgEd25519)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	blob, err := sig.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical: %v", err)
+	}
+	if len(blob) == 0 {
+		t.Error("Canonical: empty blob")
+	}
+}